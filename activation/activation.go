@@ -0,0 +1,107 @@
+// Copyright 2020 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package activation implements primitives for systemd socket activation,
+// parsing the LISTEN_PID/LISTEN_FDS/LISTEN_FDNAMES protocol described in
+// sd_listen_fds(3).
+package activation
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// listenFdsStart is the first file descriptor systemd passes to an
+// activated process, matching libsystemd's SD_LISTEN_FDS_START.
+const listenFdsStart = 3
+
+var (
+	listenFdsOnce sync.Once
+	listenFiles   []*os.File
+)
+
+// Files returns a *os.File for each file descriptor systemd passed to this
+// process via LISTEN_PID/LISTEN_FDS/LISTEN_FDNAMES, in the order they were
+// passed. It returns nil if LISTEN_PID doesn't name the calling process
+// (e.g. a forked child that inherited the environment but not the sockets)
+// or if LISTEN_FDS is unset or zero.
+//
+// The environment is only ever parsed once per process, regardless of how
+// many times Files is called or with which unsetEnv value: each raw file
+// descriptor must be wrapped in exactly one *os.File, since closing or
+// garbage-collecting one wrapper closes the underlying fd out from under
+// any other wrapper of the same number. Callers that need multiple views
+// of the activated sockets (e.g. both Listeners and PacketConns) can
+// therefore call Files, Listeners, PacketConns, and FilesWithNames freely
+// in any combination; unsetEnv is honored only on the first call.
+func Files(unsetEnv bool) []*os.File {
+	listenFdsOnce.Do(func() {
+		listenFiles = parseFiles(unsetEnv)
+	})
+	return listenFiles
+}
+
+func parseFiles(unsetEnv bool) []*os.File {
+	if unsetEnv {
+		defer os.Unsetenv("LISTEN_PID")
+		defer os.Unsetenv("LISTEN_FDS")
+		defer os.Unsetenv("LISTEN_FDNAMES")
+	}
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil
+	}
+
+	nfds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || nfds == 0 {
+		return nil
+	}
+
+	var names []string
+	if fdnames := os.Getenv("LISTEN_FDNAMES"); fdnames != "" {
+		names = strings.Split(fdnames, ":")
+	}
+
+	files := make([]*os.File, 0, nfds)
+	for i := 0; i < nfds; i++ {
+		fd := listenFdsStart + i
+		unix.FcntlInt(uintptr(fd), unix.F_SETFD, unix.FD_CLOEXEC)
+
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		files = append(files, os.NewFile(uintptr(fd), name))
+	}
+
+	return files
+}
+
+// FilesWithNames is like Files, but groups the returned descriptors by the
+// name assigned to them (via LISTEN_FDNAMES, e.g. systemd socket units'
+// FileDescriptorName=, or Notification.Fds's "FDNAME="). Descriptors
+// without an explicit name are keyed by their generated "LISTEN_FD_N" name.
+func FilesWithNames(unsetEnv bool) map[string][]*os.File {
+	byName := make(map[string][]*os.File)
+	for _, f := range Files(unsetEnv) {
+		byName[f.Name()] = append(byName[f.Name()], f)
+	}
+	return byName
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package activation
+
+import "net"
+
+// PacketConns returns a net.PacketConn for each socket-activated file
+// descriptor passed to this process that can be wrapped as one (UDP and
+// Unix datagram sockets). Descriptors that aren't packet sockets are
+// skipped, leaving a nil entry in their place so the result stays aligned
+// with Files. It shares the same cached, parsed-once descriptors as Files,
+// Listeners, and FilesWithNames, so it's safe to call alongside them in
+// the same process: net.FilePacketConn dup's the fd internally, and the
+// cached *os.File itself is left open (not closed here), so a later call
+// to any of the other helpers still sees a usable descriptor.
+func PacketConns() ([]net.PacketConn, error) {
+	files := Files(true)
+	conns := make([]net.PacketConn, len(files))
+
+	for i, f := range files {
+		if c, err := net.FilePacketConn(f); err == nil {
+			conns[i] = c
+		}
+	}
+
+	return conns, nil
+}
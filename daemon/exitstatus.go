@@ -0,0 +1,66 @@
+// Copyright 2020 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+)
+
+// LSB init-script exit codes, as used by systemd's RestartPreventExitStatus=,
+// SuccessExitStatus=, and Restart=on-failure policies.
+const (
+	ExitSuccess               = 0
+	ExitGeneric               = 1
+	ExitInvalidArgument       = 2
+	ExitNotImplemented        = 3
+	ExitInsufficientPrivilege = 4
+	ExitNotInstalled          = 5
+	ExitNotConfigured         = 6
+	ExitNotRunning            = 7
+)
+
+// BSD sysexits.h exit codes, also meaningful to the systemd exit status
+// policies listed above.
+const (
+	ExitUsage       = 64
+	ExitDataErr     = 65
+	ExitNoInput     = 66
+	ExitNoUser      = 67
+	ExitNoHost      = 68
+	ExitUnavailable = 69
+	ExitSoftware    = 70
+	ExitOSErr       = 71
+	ExitOSFile      = 72
+	ExitCantCreat   = 73
+	ExitIOErr       = 74
+	ExitTempFail    = 75
+	ExitProtocol    = 76
+	ExitNoPerm      = 77
+	ExitConfig      = 78
+)
+
+// Exit reports "STOPPING=1" and a STATUS= summary to systemd when notify is
+// true and NOTIFY_SOCKET is set, then terminates the process with
+// os.Exit(code). Pass one of the constants above, or any other code
+// meaningful to the unit's exit status policy.
+func Exit(code int, notify bool) {
+	if notify && os.Getenv("NOTIFY_SOCKET") != "" {
+		state := fmt.Sprintf("STOPPING=1\nSTATUS=exiting with code %d", code)
+		_ = (&Notification{State: state}).Send(false)
+	}
+	os.Exit(code)
+}
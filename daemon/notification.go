@@ -0,0 +1,40 @@
+// Copyright 2020 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package daemon
+
+import "os"
+
+// Notification is a structured systemd notification message, modelled after
+// libsystemd's sd_pid_notify_with_fds. It covers the full notify vocabulary
+// (READY, STATUS, RELOADING, STOPPING, WATCHDOG, MAINPID, FDSTORE, BARRIER)
+// behind a single typed entry point instead of ad-hoc string sends.
+type Notification struct {
+	// PID is the process ID systemd should attribute the notification to.
+	// Leave zero to report as the calling process. Spoofing PID to a value
+	// other than os.Getpid() requires the systemd-facing socket to have
+	// SO_PASSCRED set and the caller to hold CAP_SIDEUSER (or be root), and
+	// is only supported on Linux.
+	PID int
+
+	// State is the notification payload: one or more newline-separated
+	// KEY=VALUE pairs, e.g. "READY=1\nSTATUS=Started".
+	State string
+
+	// Fds are file descriptors to hand off to the service manager's file
+	// descriptor store. State should include "FDSTORE=1" and typically
+	// "FDNAME=<name>" alongside them. Only supported on Linux.
+	Fds []*os.File
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// Send dispatches the notification to the systemd manager named by the
+// NOTIFY_SOCKET environment variable, returning ErrNoNotificationSocket if
+// it is not set. If unsetEnvironment is true, NOTIFY_SOCKET is unconditionally
+// unset, regardless of whether the send itself succeeds.
+//
+// If n.Fds is non-empty, the descriptors are attached as SCM_RIGHTS
+// ancillary data for the service manager's file descriptor store.
+//
+// If n.PID is positive and differs from the caller's own PID, an
+// SCM_CREDENTIALS control message is attached so systemd attributes the
+// notification to that PID instead of the sender's.
+func (n *Notification) Send(unsetEnvironment bool) error {
+	socketAddr := &net.UnixAddr{
+		Name: os.Getenv("NOTIFY_SOCKET"),
+		Net:  "unixgram",
+	}
+	if socketAddr.Name == "" {
+		return ErrNoNotificationSocket
+	}
+
+	if unsetEnvironment {
+		if err := os.Unsetenv("NOTIFY_SOCKET"); err != nil {
+			return err
+		}
+	}
+
+	conn, err := net.DialUnix(socketAddr.Net, nil, socketAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	connf, err := conn.File()
+	if err != nil {
+		return err
+	}
+	defer connf.Close()
+
+	var oob []byte
+	if len(n.Fds) > 0 {
+		fds := make([]int, len(n.Fds))
+		for i, f := range n.Fds {
+			fds[i] = int(f.Fd())
+		}
+		oob = append(oob, syscall.UnixRights(fds...)...)
+	}
+
+	spoofingPID := n.PID > 0 && n.PID != os.Getpid()
+	if spoofingPID {
+		cred := &syscall.Ucred{
+			Pid: int32(n.PID),
+			Uid: uint32(os.Getuid()),
+			Gid: uint32(os.Getgid()),
+		}
+		oob = append(oob, syscall.UnixCredentials(cred)...)
+	}
+
+	if err := syscall.Sendmsg(int(connf.Fd()), []byte(n.State), oob, nil, 0); err != nil {
+		if spoofingPID {
+			return fmt.Errorf("daemon: sendmsg with SCM_CREDENTIALS for PID %d (requires SO_PASSCRED on the systemd socket and CAP_SIDEUSER or root): %w", n.PID, err)
+		}
+		return err
+	}
+
+	return nil
+}
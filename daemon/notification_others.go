@@ -0,0 +1,63 @@
+// Copyright 2020 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//go:build !linux
+
+package daemon
+
+import (
+	"errors"
+	"net"
+	"os"
+)
+
+// ErrFdPassingUnsupported is returned by Notification.Send on platforms
+// other than Linux when the notification carries file descriptors or a
+// spoofed PID, neither of which sd_pid_notify_with_fds-style ancillary data
+// is implemented for outside Linux.
+var ErrFdPassingUnsupported = errors.New("daemon: file descriptor passing and PID spoofing are only supported on Linux")
+
+// Send dispatches n.State as a plain datagram notification to the systemd
+// manager named by NOTIFY_SOCKET, returning ErrNoNotificationSocket if it is
+// not set. n.Fds and a spoofed n.PID are rejected with
+// ErrFdPassingUnsupported rather than silently ignored.
+func (n *Notification) Send(unsetEnvironment bool) error {
+	if len(n.Fds) > 0 || (n.PID > 0 && n.PID != os.Getpid()) {
+		return ErrFdPassingUnsupported
+	}
+
+	socketAddr := &net.UnixAddr{
+		Name: os.Getenv("NOTIFY_SOCKET"),
+		Net:  "unixgram",
+	}
+	if socketAddr.Name == "" {
+		return ErrNoNotificationSocket
+	}
+
+	if unsetEnvironment {
+		if err := os.Unsetenv("NOTIFY_SOCKET"); err != nil {
+			return err
+		}
+	}
+
+	conn, err := net.DialUnix(socketAddr.Net, nil, socketAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(n.State))
+	return err
+}
@@ -0,0 +1,30 @@
+// Copyright 2020 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+//go:build !linux
+
+package daemon
+
+import "errors"
+
+// errMonotonicUnavailable is returned by monotonicUsec on platforms where
+// reading CLOCK_MONOTONIC through this package isn't implemented.
+// SdNotifyReloading treats this as non-fatal and simply omits
+// MONOTONIC_USEC from the notification.
+var errMonotonicUnavailable = errors.New("daemon: CLOCK_MONOTONIC is only read on Linux")
+
+func monotonicUsec() (int64, error) {
+	return 0, errMonotonicUnavailable
+}
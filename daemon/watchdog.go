@@ -0,0 +1,105 @@
+// Copyright 2020 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Watchdog holds the keepalive interval systemd advertises to a unit via
+// the WATCHDOG_USEC/WATCHDOG_PID environment variables, as set for services
+// using WatchdogSec=.
+type Watchdog struct {
+	interval time.Duration
+}
+
+// NewWatchdog reads WATCHDOG_USEC and WATCHDOG_PID from the environment,
+// mirroring libsystemd's sd_watchdog_enabled. It returns a nil Watchdog and
+// a nil error if the calling process isn't expected to send keepalives,
+// either because WATCHDOG_USEC is unset or zero, or because WATCHDOG_PID
+// names a different process.
+func NewWatchdog() (*Watchdog, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return nil, nil
+	}
+
+	if pidStr := os.Getenv("WATCHDOG_PID"); pidStr != "" {
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil {
+			return nil, fmt.Errorf("daemon: invalid WATCHDOG_PID %q: %w", pidStr, err)
+		}
+		if pid != os.Getpid() {
+			return nil, nil
+		}
+	}
+
+	n, err := strconv.ParseUint(usec, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("daemon: invalid WATCHDOG_USEC %q: %w", usec, err)
+	}
+	if n == 0 {
+		return nil, nil
+	}
+
+	return &Watchdog{interval: time.Duration(n) * time.Microsecond}, nil
+}
+
+// Interval returns the watchdog timeout configured by systemd.
+func (w *Watchdog) Interval() time.Duration {
+	return w.interval
+}
+
+// Start sends an immediate "WATCHDOG=1" keepalive and then spawns a
+// goroutine that repeats it every Interval()/2, as recommended by
+// sd_watchdog_enabled(3), until ctx is done.
+func (w *Watchdog) Start(ctx context.Context) error {
+	if err := w.Notify(); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(w.interval / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = w.Notify()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Notify sends a single "WATCHDOG=1" keepalive ping.
+func (w *Watchdog) Notify() error {
+	return (&Notification{State: "WATCHDOG=1"}).Send(false)
+}
+
+// Trigger tells systemd that the watchdog check has failed, causing the
+// unit to be treated as failed per its Restart=/WatchdogSec= policy. reason
+// is reported as WATCHDOG_MESSAGE for inclusion in logs.
+func (w *Watchdog) Trigger(reason string) error {
+	state := fmt.Sprintf("WATCHDOG=trigger\nWATCHDOG_MESSAGE=%s", reason)
+	return (&Notification{State: state}).Send(false)
+}